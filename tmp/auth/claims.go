@@ -0,0 +1,24 @@
+package auth
+
+import "github.com/golang-jwt/jwt/v5"
+
+// tokenType distinguishes access tokens from refresh tokens so one can
+// never be presented in place of the other.
+type tokenType string
+
+const (
+	tokenTypeAccess     tokenType = "access"
+	tokenTypeRefresh    tokenType = "refresh"
+	tokenTypeMFAPending tokenType = "mfa_pending"
+)
+
+// Claims is the JWT payload used for both access and refresh tokens.
+// Family is only populated on refresh tokens and identifies the rotation
+// chain a token belongs to, so TokenStore can detect reuse of a
+// superseded token.
+type Claims struct {
+	jwt.RegisteredClaims
+	UserID string    `json:"uid"`
+	Type   tokenType `json:"typ"`
+	Family string    `json:"fam,omitempty"`
+}
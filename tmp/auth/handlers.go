@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"usermanagement/models"
+	"usermanagement/passwords"
+)
+
+// UserLookup authenticates credentials against a user record, rehashing
+// and persisting the password in the same transaction if it was stored
+// under a weaker hashing policy. It is satisfied by *store.UserStore.
+type UserLookup interface {
+	VerifyLogin(ctx context.Context, email, password string, hasher passwords.Hasher) (*models.User, error)
+}
+
+// Handlers exposes the login/refresh/logout HTTP endpoints.
+type Handlers struct {
+	issuer *Issuer
+	users  UserLookup
+	hasher passwords.Hasher
+}
+
+// NewHandlers builds the auth Handlers. hasher is the current password
+// hashing policy, used to rehash passwords stored under a weaker one.
+func NewHandlers(issuer *Issuer, users UserLookup, hasher passwords.Hasher) *Handlers {
+	return &Handlers{issuer: issuer, users: users, hasher: hasher}
+}
+
+type loginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// mfaRequiredResponse is returned by Login in place of a token pair
+// when the user has 2FA enabled; the client exchanges MFAToken for a
+// token pair via POST /2fa/verify or /2fa/recover.
+type mfaRequiredResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+// Login validates credentials and, on success, issues a new access/refresh
+// token pair, or an mfaRequiredResponse if the user has 2FA enabled.
+func (h *Handlers) Login(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.users.VerifyLogin(c.Request.Context(), req.Email, req.Password, h.hasher)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify credentials"})
+		return
+	}
+
+	if user.TOTPEnabled {
+		mfaToken, err := h.issuer.IssueMFAPending(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start 2fa challenge"})
+			return
+		}
+		c.JSON(http.StatusOK, mfaRequiredResponse{MFARequired: true, MFAToken: mfaToken})
+		return
+	}
+
+	pair, err := h.issuer.IssuePair(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{AccessToken: pair.AccessToken, RefreshToken: pair.RefreshToken})
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh rotates a refresh token for a new access/refresh pair, revoking
+// the whole token family if reuse of an already-consumed token is
+// detected.
+func (h *Handlers) Refresh(c *gin.Context) {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, err := h.issuer.Rotate(c.Request.Context(), req.RefreshToken)
+	if err == ErrTokenReuse {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token already used; session revoked"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{AccessToken: pair.AccessToken, RefreshToken: pair.RefreshToken})
+}
+
+// LoginKerberos issues a token pair for the user resolved by SPNEGO
+// middleware, so downstream handlers stay agnostic to whether the
+// session started with a password or with Kerberos SSO. Must run behind
+// a SPNEGO middleware.
+func (h *Handlers) LoginKerberos(c *gin.Context) {
+	user, ok := UserFromSPNEGOContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing kerberos authentication"})
+		return
+	}
+
+	pair, err := h.issuer.IssuePair(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{AccessToken: pair.AccessToken, RefreshToken: pair.RefreshToken})
+}
+
+// Logout revokes the caller's current access token and every outstanding
+// refresh-token family, so none of the user's sessions can be refreshed.
+// Access tokens held by the user's other sessions are not revoked and
+// stay valid until they expire. Must run behind Verifier.Middleware.
+func (h *Handlers) Logout(c *gin.Context) {
+	claims, ok := ClaimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authentication"})
+		return
+	}
+
+	if err := h.issuer.Logout(c.Request.Context(), claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
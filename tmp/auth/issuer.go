@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrTokenReuse is returned by Rotate when a refresh token that was
+// already consumed is presented again, indicating the token may have
+// been stolen. Callers should treat this as a signal to force
+// re-authentication.
+var ErrTokenReuse = errors.New("auth: refresh token reuse detected")
+
+// TokenPair is an access/refresh token issued together.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Issuer signs access and refresh tokens and rotates refresh tokens on
+// use, recording each rotation chain in a TokenStore.
+type Issuer struct {
+	accessSecret  []byte
+	refreshSecret []byte
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+	mfaPendingTTL time.Duration
+	store         TokenStore
+}
+
+// NewIssuer builds an Issuer with the given signing secrets, token
+// lifetimes, and revocation store. mfaPendingTTL bounds how long a user
+// with 2FA enabled has to complete /2fa/verify after a successful
+// password check before having to log in again.
+func NewIssuer(accessSecret, refreshSecret []byte, accessTTL, refreshTTL, mfaPendingTTL time.Duration, store TokenStore) *Issuer {
+	return &Issuer{
+		accessSecret:  accessSecret,
+		refreshSecret: refreshSecret,
+		accessTTL:     accessTTL,
+		refreshTTL:    refreshTTL,
+		mfaPendingTTL: mfaPendingTTL,
+		store:         store,
+	}
+}
+
+// IssuePair mints a new access token and a new refresh-token family for
+// userID, as performed on login.
+func (iss *Issuer) IssuePair(ctx context.Context, userID string) (TokenPair, error) {
+	family := uuid.NewString()
+	return iss.issuePairForFamily(ctx, userID, family)
+}
+
+// Rotate verifies refreshToken, checks it against its rotation family in
+// the TokenStore, and — if valid and unconsumed — issues a fresh pair in
+// the same family. If the token was already consumed, the whole family
+// is revoked and ErrTokenReuse is returned.
+func (iss *Issuer) Rotate(ctx context.Context, refreshToken string) (TokenPair, error) {
+	claims, err := iss.parse(refreshToken, iss.refreshSecret, tokenTypeRefresh)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	ok, err := iss.store.ConsumeRefreshJTI(ctx, claims.Family, claims.ID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !ok {
+		_ = iss.store.RevokeFamily(ctx, claims.Family)
+		return TokenPair{}, ErrTokenReuse
+	}
+
+	return iss.issuePairForFamily(ctx, claims.UserID, claims.Family)
+}
+
+func (iss *Issuer) issuePairForFamily(ctx context.Context, userID, family string) (TokenPair, error) {
+	access, _, err := iss.signAccess(userID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshJTI := uuid.NewString()
+	refresh, err := iss.signRefresh(userID, family, refreshJTI)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := iss.store.SaveRefreshJTI(ctx, userID, family, refreshJTI, iss.refreshTTL); err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+func (iss *Issuer) signAccess(userID string) (token string, jti string, err error) {
+	jti = uuid.NewString()
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(iss.accessTTL)),
+		},
+		UserID: userID,
+		Type:   tokenTypeAccess,
+	}
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(iss.accessSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("auth: sign access token: %w", err)
+	}
+	return token, jti, nil
+}
+
+func (iss *Issuer) signRefresh(userID, family, jti string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(iss.refreshTTL)),
+		},
+		UserID: userID,
+		Type:   tokenTypeRefresh,
+		Family: family,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(iss.refreshSecret)
+	if err != nil {
+		return "", fmt.Errorf("auth: sign refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// parse validates a token's signature, expiry, and expected type.
+func (iss *Issuer) parse(token string, secret []byte, want tokenType) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("auth: unexpected signing method %v", t.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth: invalid token: %w", err)
+	}
+	if !parsed.Valid {
+		return nil, fmt.Errorf("auth: invalid token")
+	}
+	if claims.Type != want {
+		return nil, fmt.Errorf("auth: unexpected token type %q", claims.Type)
+	}
+	return claims, nil
+}
+
+// VerifyAccess parses and validates an access token, returning its claims.
+func (iss *Issuer) VerifyAccess(token string) (*Claims, error) {
+	return iss.parse(token, iss.accessSecret, tokenTypeAccess)
+}
+
+// IssueMFAPending mints a short-lived token proving userID passed a
+// password check, to be exchanged for a full token pair by /2fa/verify
+// or /2fa/recover once they also pass their second factor.
+func (iss *Issuer) IssueMFAPending(userID string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(iss.mfaPendingTTL)),
+		},
+		UserID: userID,
+		Type:   tokenTypeMFAPending,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(iss.accessSecret)
+	if err != nil {
+		return "", fmt.Errorf("auth: sign mfa-pending token: %w", err)
+	}
+	return token, nil
+}
+
+// VerifyMFAPending parses and validates a token issued by
+// IssueMFAPending.
+func (iss *Issuer) VerifyMFAPending(token string) (*Claims, error) {
+	return iss.parse(token, iss.accessSecret, tokenTypeMFAPending)
+}
+
+// Logout revokes the presented access token and every refresh-token
+// family for its owner, so the presented token stops working
+// immediately and none of the user's sessions can be refreshed. Other
+// access tokens issued to the user are not revoked and stay valid until
+// they expire.
+func (iss *Issuer) Logout(ctx context.Context, claims *Claims) error {
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if err := iss.store.RevokeAccessJTI(ctx, claims.ID, ttl); err != nil {
+		return err
+	}
+	return iss.store.RevokeAllForUser(ctx, claims.UserID)
+}
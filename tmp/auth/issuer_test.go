@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memTokenStore is an in-memory TokenStore used only for tests.
+type memTokenStore struct {
+	mu       sync.Mutex
+	families map[string]string
+	revoked  map[string]bool
+	userFams map[string][]string
+}
+
+func newMemTokenStore() *memTokenStore {
+	return &memTokenStore{
+		families: map[string]string{},
+		revoked:  map[string]bool{},
+		userFams: map[string][]string{},
+	}
+}
+
+func (m *memTokenStore) SaveRefreshJTI(_ context.Context, userID, family, jti string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.families[family] = jti
+	m.userFams[userID] = append(m.userFams[userID], family)
+	return nil
+}
+
+func (m *memTokenStore) ConsumeRefreshJTI(_ context.Context, family, jti string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.families[family]
+	if !ok || current != jti {
+		return false, nil
+	}
+	delete(m.families, family)
+	return true, nil
+}
+
+func (m *memTokenStore) RevokeFamily(_ context.Context, family string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.families, family)
+	return nil
+}
+
+func (m *memTokenStore) RevokeAllForUser(_ context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range m.userFams[userID] {
+		delete(m.families, f)
+	}
+	delete(m.userFams, userID)
+	return nil
+}
+
+func (m *memTokenStore) RevokeAccessJTI(_ context.Context, jti string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoked[jti] = true
+	return nil
+}
+
+func (m *memTokenStore) IsAccessJTIRevoked(_ context.Context, jti string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.revoked[jti], nil
+}
+
+func newTestIssuer() (*Issuer, *memTokenStore) {
+	store := newMemTokenStore()
+	issuer := NewIssuer([]byte("access-secret"), []byte("refresh-secret"), time.Minute, time.Hour, 5*time.Minute, store)
+	return issuer, store
+}
+
+func TestIssuePairAndVerify(t *testing.T) {
+	issuer, _ := newTestIssuer()
+
+	pair, err := issuer.IssuePair(context.Background(), "user-1")
+	require.NoError(t, err)
+
+	claims, err := issuer.VerifyAccess(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+}
+
+func TestRotateIssuesFreshPair(t *testing.T) {
+	issuer, _ := newTestIssuer()
+	ctx := context.Background()
+
+	first, err := issuer.IssuePair(ctx, "user-1")
+	require.NoError(t, err)
+
+	second, err := issuer.Rotate(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.NotEqual(t, first.AccessToken, second.AccessToken)
+}
+
+func TestRotateDetectsReuseAndRevokesFamily(t *testing.T) {
+	issuer, _ := newTestIssuer()
+	ctx := context.Background()
+
+	first, err := issuer.IssuePair(ctx, "user-1")
+	require.NoError(t, err)
+
+	second, err := issuer.Rotate(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = issuer.Rotate(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenReuse)
+
+	// The reuse revoked the whole family, so the legitimately rotated
+	// token no longer works either.
+	_, err = issuer.Rotate(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, ErrTokenReuse)
+}
+
+func TestIssueMFAPendingAndVerify(t *testing.T) {
+	issuer, _ := newTestIssuer()
+
+	token, err := issuer.IssueMFAPending("user-1")
+	require.NoError(t, err)
+
+	claims, err := issuer.VerifyMFAPending(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+}
+
+func TestVerifyMFAPendingRejectsAccessToken(t *testing.T) {
+	issuer, _ := newTestIssuer()
+
+	pair, err := issuer.IssuePair(context.Background(), "user-1")
+	require.NoError(t, err)
+
+	_, err = issuer.VerifyMFAPending(pair.AccessToken)
+	assert.Error(t, err)
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	issuer, _ := newTestIssuer()
+	ctx := context.Background()
+
+	pair, err := issuer.IssuePair(ctx, "user-1")
+	require.NoError(t, err)
+
+	claims, err := issuer.VerifyAccess(pair.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, issuer.Logout(ctx, claims))
+
+	revoked, err := issuer.store.IsAccessJTIRevoked(ctx, claims.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
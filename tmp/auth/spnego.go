@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jcmturner/goidentity/v6"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+
+	"usermanagement/models"
+)
+
+// contextKeyKerberosUser is the gin context key under which the user
+// resolved by SPNEGO middleware is stored.
+const contextKeyKerberosUser = "auth.kerberos_user"
+
+// RealmProvisioner resolves an authenticated Kerberos principal's email
+// to a local user, provisioning one just-in-time on first login.
+type RealmProvisioner interface {
+	FindOrProvisionByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// SPNEGOConfig configures the Kerberos SSO middleware.
+type SPNEGOConfig struct {
+	// KeytabPath is the path to the service's Kerberos keytab file.
+	KeytabPath string
+	// ServicePrincipal, if set, restricts ticket validation to this
+	// specific principal in the keytab rather than trying all of them.
+	ServicePrincipal string
+	// RealmEmailDomains maps a Kerberos realm (e.g. "CORP.EXAMPLE.COM")
+	// to the email domain used to provision/find the local user
+	// authenticated under that realm (e.g. "example.com"). Realms not
+	// present in this map are rejected.
+	RealmEmailDomains map[string]string
+}
+
+// SPNEGO builds Gin middleware that authenticates requests carrying an
+// `Authorization: Negotiate` header against the keytab at
+// cfg.KeytabPath. On success it finds or just-in-time provisions the
+// matching local user (mapping the Kerberos principal's realm to an
+// email domain via cfg.RealmEmailDomains) and stores it in the request
+// context for UserFromSPNEGOContext.
+func SPNEGO(cfg SPNEGOConfig, provisioner RealmProvisioner) (gin.HandlerFunc, error) {
+	kt, err := keytab.Load(cfg.KeytabPath)
+	if err != nil {
+		return nil, fmt.Errorf("auth: load keytab: %w", err)
+	}
+
+	var settings []func(*service.Settings)
+	if cfg.ServicePrincipal != "" {
+		settings = append(settings, service.KeytabPrincipal(cfg.ServicePrincipal))
+	}
+
+	return func(c *gin.Context) {
+		var id goidentity.Identity
+		inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id = goidentity.FromHTTPRequestContext(r)
+		})
+
+		// SPNEGOKRB5Authenticate writes its own 401/WWW-Authenticate
+		// response directly to c.Writer when the ticket is missing or
+		// invalid, so on failure we just need to stop the gin chain.
+		spnego.SPNEGOKRB5Authenticate(inner, kt, settings...).ServeHTTP(c.Writer, c.Request)
+		if id == nil || !id.Authenticated() {
+			c.Abort()
+			return
+		}
+
+		domain, ok := cfg.RealmEmailDomains[id.Domain()]
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("realm %q is not permitted to authenticate", id.Domain())})
+			return
+		}
+
+		user, err := provisioner.FindOrProvisionByEmail(c.Request.Context(), id.UserName()+"@"+domain)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve kerberos identity"})
+			return
+		}
+
+		c.Set(contextKeyKerberosUser, user)
+		c.Next()
+	}, nil
+}
+
+// UserFromSPNEGOContext retrieves the user resolved by SPNEGO middleware.
+func UserFromSPNEGOContext(c *gin.Context) (*models.User, bool) {
+	v, ok := c.Get(contextKeyKerberosUser)
+	if !ok {
+		return nil, false
+	}
+	user, ok := v.(*models.User)
+	return user, ok
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenStore tracks refresh-token rotation chains ("families") and
+// revoked token IDs (JTIs) so that logout and password-change flows can
+// invalidate outstanding tokens immediately, something a stateless JWT
+// cannot do on its own.
+type TokenStore interface {
+	// SaveRefreshJTI records jti as the current, unconsumed token for
+	// family, expiring it after ttl.
+	SaveRefreshJTI(ctx context.Context, userID, family, jti string, ttl time.Duration) error
+	// ConsumeRefreshJTI atomically checks that jti is still the current
+	// token for family. It returns false if the token was already
+	// consumed or never existed, which callers must treat as token
+	// reuse and respond to by revoking the whole family.
+	ConsumeRefreshJTI(ctx context.Context, family, jti string) (bool, error)
+	// RevokeFamily invalidates every outstanding refresh token in a
+	// rotation chain.
+	RevokeFamily(ctx context.Context, family string) error
+	// RevokeAllForUser invalidates every refresh-token family issued to
+	// userID, used on logout.
+	RevokeAllForUser(ctx context.Context, userID string) error
+	// RevokeAccessJTI blacklists an access token's JTI until it would
+	// have expired naturally.
+	RevokeAccessJTI(ctx context.Context, jti string, ttl time.Duration) error
+	// IsAccessJTIRevoked reports whether an access token JTI has been
+	// blacklisted.
+	IsAccessJTIRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// redisTokenStore is the Redis-backed TokenStore used in production.
+type redisTokenStore struct {
+	client *redis.Client
+}
+
+// NewRedisTokenStore builds a TokenStore backed by the given Redis client.
+func NewRedisTokenStore(client *redis.Client) TokenStore {
+	return &redisTokenStore{client: client}
+}
+
+// consumeRefreshScript atomically checks that the family's current token
+// matches the presented jti and deletes it, so two concurrent rotations
+// racing on the same refresh token can't both observe it as still valid.
+var consumeRefreshScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == false or current ~= ARGV[1] then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
+func familyKey(family string) string {
+	return fmt.Sprintf("auth:refresh:family:%s", family)
+}
+
+func userFamiliesKey(userID string) string {
+	return fmt.Sprintf("auth:user:%s:families", userID)
+}
+
+func revokedAccessKey(jti string) string {
+	return fmt.Sprintf("auth:revoked:access:%s", jti)
+}
+
+func (s *redisTokenStore) SaveRefreshJTI(ctx context.Context, userID, family, jti string, ttl time.Duration) error {
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, familyKey(family), jti, ttl)
+	pipe.SAdd(ctx, userFamiliesKey(userID), family)
+	pipe.Expire(ctx, userFamiliesKey(userID), ttl)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("auth: save refresh jti: %w", err)
+	}
+	return nil
+}
+
+func (s *redisTokenStore) ConsumeRefreshJTI(ctx context.Context, family, jti string) (bool, error) {
+	consumed, err := consumeRefreshScript.Run(ctx, s.client, []string{familyKey(family)}, jti).Int()
+	if err != nil {
+		return false, fmt.Errorf("auth: consume refresh jti: %w", err)
+	}
+	return consumed == 1, nil
+}
+
+func (s *redisTokenStore) RevokeFamily(ctx context.Context, family string) error {
+	if err := s.client.Del(ctx, familyKey(family)).Err(); err != nil {
+		return fmt.Errorf("auth: revoke family: %w", err)
+	}
+	return nil
+}
+
+func (s *redisTokenStore) RevokeAllForUser(ctx context.Context, userID string) error {
+	families, err := s.client.SMembers(ctx, userFamiliesKey(userID)).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("auth: revoke all for user: %w", err)
+	}
+	if len(families) == 0 {
+		return nil
+	}
+	keys := make([]string, len(families))
+	for i, f := range families {
+		keys[i] = familyKey(f)
+	}
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.Del(ctx, userFamiliesKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("auth: revoke all for user: %w", err)
+	}
+	return nil
+}
+
+func (s *redisTokenStore) RevokeAccessJTI(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.client.Set(ctx, revokedAccessKey(jti), "1", ttl).Err(); err != nil {
+		return fmt.Errorf("auth: revoke access jti: %w", err)
+	}
+	return nil
+}
+
+func (s *redisTokenStore) IsAccessJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	_, err := s.client.Get(ctx, revokedAccessKey(jti)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("auth: check revoked access jti: %w", err)
+	}
+	return true, nil
+}
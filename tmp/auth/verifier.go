@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey is the gin context key under which validated claims are
+// stored by Verifier.
+const contextKey = "auth.claims"
+
+// Verifier validates access tokens on incoming requests.
+type Verifier struct {
+	issuer *Issuer
+	store  TokenStore
+}
+
+// NewVerifier builds a Verifier that checks tokens against issuer and
+// consults store for revocation.
+func NewVerifier(issuer *Issuer, store TokenStore) *Verifier {
+	return &Verifier{issuer: issuer, store: store}
+}
+
+// Middleware validates the bearer access token on the request, rejecting
+// it with 401 if missing, invalid, expired, or revoked, and otherwise
+// injects its Claims into the request context.
+func (v *Verifier) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		claims, err := v.issuer.VerifyAccess(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			return
+		}
+
+		revoked, err := v.store.IsAccessJTIRevoked(c.Request.Context(), claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			return
+		}
+
+		c.Set(contextKey, claims)
+		c.Next()
+	}
+}
+
+// ClaimsFromContext retrieves the Claims injected by Middleware.
+func ClaimsFromContext(c *gin.Context) (*Claims, bool) {
+	v, ok := c.Get(contextKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(*Claims)
+	return claims, ok
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, prefix))
+}
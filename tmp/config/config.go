@@ -0,0 +1,229 @@
+// Package config loads runtime configuration from the environment.
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds all environment-derived settings for the service.
+type Config struct {
+	DatabaseURL string
+	RedisAddr   string
+	RedisDB     int
+
+	JWTAccessSecret  string
+	JWTRefreshSecret string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	// MFAPendingTTL bounds how long a user has to complete /2fa/verify
+	// or /2fa/recover after a successful password check.
+	MFAPendingTTL time.Duration
+
+	// TOTPEncryptionKey is a 32-byte AES-256 key (base64-encoded in the
+	// environment) used to encrypt TOTP secrets at rest.
+	TOTPEncryptionKey []byte
+
+	DefaultPhoneRegion string
+
+	// KerberosKeytabPath, when set, enables the /login/kerberos SSO
+	// endpoint.
+	KerberosKeytabPath       string
+	KerberosServicePrincipal string
+	// KerberosRealmEmailDomains maps a Kerberos realm to the email
+	// domain used to find/provision its users, e.g.
+	// "CORP.EXAMPLE.COM:example.com,DEV.EXAMPLE.COM:dev.example.com".
+	KerberosRealmEmailDomains map[string]string
+
+	// Argon2 tunables for the password hashing policy. Defaults are
+	// deliberately conservative; use the passwords package's
+	// benchmarks to pick tighter values for a given deploy host.
+	Argon2MemoryKiB   uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+
+	// RateLimitBackend selects the ratelimit package's storage: "memory"
+	// (single-instance only) or "redis" (shared across instances).
+	RateLimitBackend             string
+	RateLimitPerIPPerMinute      int
+	RateLimitPerSubjectPerMinute int
+	// RateLimitLockoutThreshold is how many failed attempts for the
+	// same subject (username, or hashed token for token-based flows)
+	// within RateLimitLockoutWindow trigger a hard lockout.
+	RateLimitLockoutThreshold int
+	RateLimitLockoutWindow    time.Duration
+	// RateLimitBackoffBase is the delay imposed after a subject's first
+	// failure, doubling with each subsequent failure until the lockout
+	// threshold is reached.
+	RateLimitBackoffBase time.Duration
+}
+
+// Load reads a .env file if present (ignored when missing) and assembles a
+// Config from environment variables, returning an error if anything
+// required is absent.
+func Load() (*Config, error) {
+	_ = godotenv.Load()
+
+	cfg := &Config{
+		DatabaseURL:        os.Getenv("DATABASE_URL"),
+		RedisAddr:          getEnvDefault("REDIS_ADDR", "localhost:6379"),
+		JWTAccessSecret:    os.Getenv("JWT_ACCESS_SECRET"),
+		JWTRefreshSecret:   os.Getenv("JWT_REFRESH_SECRET"),
+		DefaultPhoneRegion: getEnvDefault("DEFAULT_PHONE_REGION", "US"),
+
+		KerberosKeytabPath:        os.Getenv("KERBEROS_KEYTAB_PATH"),
+		KerberosServicePrincipal:  os.Getenv("KERBEROS_SERVICE_PRINCIPAL"),
+		KerberosRealmEmailDomains: parseRealmEmailDomains(os.Getenv("KERBEROS_REALM_EMAIL_DOMAINS")),
+	}
+
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("config: DATABASE_URL is required")
+	}
+	if cfg.JWTAccessSecret == "" {
+		return nil, fmt.Errorf("config: JWT_ACCESS_SECRET is required")
+	}
+	if cfg.JWTRefreshSecret == "" {
+		return nil, fmt.Errorf("config: JWT_REFRESH_SECRET is required")
+	}
+
+	rawTOTPKey := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if rawTOTPKey == "" {
+		return nil, fmt.Errorf("config: TOTP_ENCRYPTION_KEY is required")
+	}
+	totpKey, err := base64.StdEncoding.DecodeString(rawTOTPKey)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid TOTP_ENCRYPTION_KEY: %w", err)
+	}
+	if len(totpKey) != 32 {
+		return nil, fmt.Errorf("config: TOTP_ENCRYPTION_KEY must decode to 32 bytes, got %d", len(totpKey))
+	}
+	cfg.TOTPEncryptionKey = totpKey
+
+	accessTTL, err := durationEnv("JWT_ACCESS_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	refreshTTL, err := durationEnv("JWT_REFRESH_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	mfaPendingTTL, err := durationEnv("MFA_PENDING_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AccessTokenTTL = accessTTL
+	cfg.RefreshTokenTTL = refreshTTL
+	cfg.MFAPendingTTL = mfaPendingTTL
+
+	argon2Memory, err := uintEnv("ARGON2_MEMORY_KIB", 64*1024)
+	if err != nil {
+		return nil, err
+	}
+	argon2Iterations, err := uintEnv("ARGON2_ITERATIONS", 3)
+	if err != nil {
+		return nil, err
+	}
+	argon2Parallelism, err := uint8Env("ARGON2_PARALLELISM", 2)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Argon2MemoryKiB = uint32(argon2Memory)
+	cfg.Argon2Iterations = uint32(argon2Iterations)
+	cfg.Argon2Parallelism = argon2Parallelism
+
+	cfg.RateLimitBackend = getEnvDefault("RATE_LIMIT_BACKEND", "memory")
+
+	perIP, err := uintEnv("RATE_LIMIT_PER_IP_PER_MINUTE", 60)
+	if err != nil {
+		return nil, err
+	}
+	perSubject, err := uintEnv("RATE_LIMIT_PER_SUBJECT_PER_MINUTE", 10)
+	if err != nil {
+		return nil, err
+	}
+	lockoutThreshold, err := uintEnv("RATE_LIMIT_LOCKOUT_THRESHOLD", 5)
+	if err != nil {
+		return nil, err
+	}
+	lockoutWindow, err := durationEnv("RATE_LIMIT_LOCKOUT_WINDOW", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	backoffBase, err := durationEnv("RATE_LIMIT_BACKOFF_BASE", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RateLimitPerIPPerMinute = int(perIP)
+	cfg.RateLimitPerSubjectPerMinute = int(perSubject)
+	cfg.RateLimitLockoutThreshold = int(lockoutThreshold)
+	cfg.RateLimitLockoutWindow = lockoutWindow
+	cfg.RateLimitBackoffBase = backoffBase
+
+	return cfg, nil
+}
+
+func getEnvDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// parseRealmEmailDomains parses a "REALM:domain,REALM:domain" list into
+// a realm-to-email-domain map.
+func parseRealmEmailDomains(raw string) map[string]string {
+	domains := map[string]string{}
+	if raw == "" {
+		return domains
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		realm, domain, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		domains[strings.TrimSpace(realm)] = strings.TrimSpace(domain)
+	}
+	return domains
+}
+
+func uintEnv(key string, fallback uint64) (uint64, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("config: invalid %s: %w", key, err)
+	}
+	return v, nil
+}
+
+func uint8Env(key string, fallback uint8) (uint8, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.ParseUint(raw, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("config: invalid %s: %w", key, err)
+	}
+	return uint8(v), nil
+}
+
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("config: invalid %s: %w", key, err)
+	}
+	return d, nil
+}
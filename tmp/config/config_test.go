@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRealmEmailDomains(t *testing.T) {
+	got := parseRealmEmailDomains("CORP.EXAMPLE.COM:example.com, DEV.EXAMPLE.COM:dev.example.com")
+	assert.Equal(t, map[string]string{
+		"CORP.EXAMPLE.COM": "example.com",
+		"DEV.EXAMPLE.COM":  "dev.example.com",
+	}, got)
+}
+
+func TestParseRealmEmailDomainsEmpty(t *testing.T) {
+	assert.Equal(t, map[string]string{}, parseRealmEmailDomains(""))
+}
+
+func TestParseRealmEmailDomainsSkipsMalformedEntries(t *testing.T) {
+	got := parseRealmEmailDomains("no-colon-here,CORP.EXAMPLE.COM:example.com")
+	assert.Equal(t, map[string]string{"CORP.EXAMPLE.COM": "example.com"}, got)
+}
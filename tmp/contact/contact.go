@@ -0,0 +1,36 @@
+// Package contact validates and normalizes user-supplied phone numbers.
+package contact
+
+import (
+	"fmt"
+
+	"github.com/ttacon/libphonenumber"
+)
+
+// ParseAndNormalize parses raw as a phone number, using defaultRegion
+// (an ISO 3166-1 alpha-2 country code, e.g. "US") when raw has no
+// explicit country code. It rejects numbers that are impossible or
+// invalid for their region, returning the canonical E.164 form, a
+// human-readable national form, and the number's region.
+func ParseAndNormalize(raw, defaultRegion string) (e164, national, region string, err error) {
+	num, err := libphonenumber.Parse(raw, defaultRegion)
+	if err != nil {
+		return "", "", "", fmt.Errorf("contact: parse phone number: %w", err)
+	}
+	if !libphonenumber.IsValidNumber(num) {
+		return "", "", "", fmt.Errorf("contact: %q is not a valid phone number", raw)
+	}
+
+	e164 = libphonenumber.Format(num, libphonenumber.E164)
+	national = libphonenumber.Format(num, libphonenumber.NATIONAL)
+	region = libphonenumber.GetRegionCodeForNumber(num)
+
+	return e164, national, region, nil
+}
+
+// Describe re-derives the national form and region of an already-stored
+// E.164 number, e.g. for display after loading a user record.
+func Describe(e164 string) (national, region string, err error) {
+	_, national, region, err = ParseAndNormalize(e164, "")
+	return national, region, err
+}
@@ -0,0 +1,38 @@
+package contact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndNormalizeValidUSNumber(t *testing.T) {
+	e164, national, region, err := ParseAndNormalize("(415) 555-2671", "US")
+	require.NoError(t, err)
+	assert.Equal(t, "+14155552671", e164)
+	assert.Equal(t, "US", region)
+	assert.NotEmpty(t, national)
+}
+
+func TestParseAndNormalizeAcceptsExplicitCountryCode(t *testing.T) {
+	e164, _, region, err := ParseAndNormalize("+442083661177", "US")
+	require.NoError(t, err)
+	assert.Equal(t, "+442083661177", e164)
+	assert.Equal(t, "GB", region)
+}
+
+func TestParseAndNormalizeRejectsInvalidNumber(t *testing.T) {
+	_, _, _, err := ParseAndNormalize("123", "US")
+	assert.Error(t, err)
+}
+
+func TestDescribeRoundTrips(t *testing.T) {
+	e164, wantNational, wantRegion, err := ParseAndNormalize("(415) 555-2671", "US")
+	require.NoError(t, err)
+
+	national, region, err := Describe(e164)
+	require.NoError(t, err)
+	assert.Equal(t, wantNational, national)
+	assert.Equal(t, wantRegion, region)
+}
@@ -0,0 +1,22 @@
+// Package db manages the shared Postgres connection pool.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// Connect opens a connection pool against the given Postgres DSN and
+// verifies it with a ping.
+func Connect(dsn string) (*sql.DB, error) {
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("db: open: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		return nil, fmt.Errorf("db: ping: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,127 @@
+// Package handlers implements the HTTP handlers for user CRUD operations.
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"usermanagement/auth"
+	"usermanagement/contact"
+	"usermanagement/models"
+	"usermanagement/passwords"
+)
+
+// UserStore is the persistence interface these handlers depend on. It is
+// satisfied by *store.UserStore.
+type UserStore interface {
+	Create(ctx context.Context, email, passwordHash string, phoneE164 *string) (*models.User, error)
+	UpdateContact(ctx context.Context, id string, phoneE164 *string) (*models.User, error)
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// UserHandlers exposes the user registration and contact-update
+// endpoints. It serves no endpoint for looking users up.
+type UserHandlers struct {
+	users         UserStore
+	defaultRegion string
+	hasher        passwords.Hasher
+}
+
+// NewUserHandlers builds UserHandlers. defaultRegion (e.g. "US") is used
+// to interpret phone numbers that omit a country code, and hasher hashes
+// passwords for new accounts under the current policy.
+func NewUserHandlers(users UserStore, defaultRegion string, hasher passwords.Hasher) *UserHandlers {
+	return &UserHandlers{users: users, defaultRegion: defaultRegion, hasher: hasher}
+}
+
+type registerRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
+	Phone    string `json:"phone"`
+}
+
+// Register creates a new user, normalizing and validating the phone
+// number if one was supplied.
+func (h *UserHandlers) Register(c *gin.Context) {
+	var req registerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	phoneE164, ok := h.normalizePhone(c, req.Phone)
+	if !ok {
+		return
+	}
+
+	hash, err := h.hasher.Hash(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		return
+	}
+
+	user, err := h.users.Create(c.Request.Context(), req.Email, hash, phoneE164)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+type updateContactRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// UpdateContact normalizes and stores a new phone number for the user
+// identified by the :id path parameter. Callers may only update their own
+// contact details.
+func (h *UserHandlers) UpdateContact(c *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(c)
+	if !ok || claims.UserID != c.Param("id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "cannot modify another user's contact details"})
+		return
+	}
+
+	var req updateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	phoneE164, ok := h.normalizePhone(c, req.Phone)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.UpdateContact(c.Request.Context(), c.Param("id"), phoneE164)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update contact"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// normalizePhone validates and normalizes raw into E.164, writing a 400
+// response and returning ok=false on failure. An empty raw is treated as
+// "no phone number" and returns (nil, true).
+func (h *UserHandlers) normalizePhone(c *gin.Context, raw string) (*string, bool) {
+	if raw == "" {
+		return nil, true
+	}
+
+	e164, _, _, err := contact.ParseAndNormalize(raw, h.defaultRegion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &e164, true
+}
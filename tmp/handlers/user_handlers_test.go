@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"usermanagement/auth"
+	"usermanagement/models"
+)
+
+type fakeUserStore struct {
+	updateContactCalled bool
+}
+
+func (f *fakeUserStore) Create(ctx context.Context, email, passwordHash string, phoneE164 *string) (*models.User, error) {
+	return nil, nil
+}
+
+func (f *fakeUserStore) UpdateContact(ctx context.Context, id string, phoneE164 *string) (*models.User, error) {
+	f.updateContactCalled = true
+	return &models.User{ID: id, PhoneE164: phoneE164}, nil
+}
+
+func (f *fakeUserStore) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return nil, nil
+}
+
+func newUpdateContactContext(callerID, targetID string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/users/"+targetID+"/contact", bytes.NewBufferString(`{"phone":"+14155552671"}`))
+	c.Params = gin.Params{{Key: "id", Value: targetID}}
+	c.Set("auth.claims", &auth.Claims{UserID: callerID})
+	return c, w
+}
+
+func TestUpdateContactRejectsCallerUpdatingAnotherUser(t *testing.T) {
+	store := &fakeUserStore{}
+	h := NewUserHandlers(store, "US", nil)
+
+	c, w := newUpdateContactContext("user-1", "user-2")
+	h.UpdateContact(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, store.updateContactCalled)
+}
+
+func TestUpdateContactAllowsCallerUpdatingOwnRecord(t *testing.T) {
+	store := &fakeUserStore{}
+	h := NewUserHandlers(store, "US", nil)
+
+	c, w := newUpdateContactContext("user-1", "user-1")
+	h.UpdateContact(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, store.updateContactCalled)
+}
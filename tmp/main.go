@@ -0,0 +1,84 @@
+// Command usermanagement runs the HTTP API server.
+package main
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"usermanagement/auth"
+	"usermanagement/config"
+	"usermanagement/db"
+	"usermanagement/handlers"
+	"usermanagement/passwords"
+	"usermanagement/ratelimit"
+	"usermanagement/router"
+	"usermanagement/store"
+	"usermanagement/twofactor"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	conn, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("db: %v", err)
+	}
+	defer conn.Close()
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: cfg.RedisAddr,
+		DB:   cfg.RedisDB,
+	})
+	defer redisClient.Close()
+
+	argon2Params := passwords.DefaultArgon2idParams()
+	argon2Params.MemoryKiB = cfg.Argon2MemoryKiB
+	argon2Params.Iterations = cfg.Argon2Iterations
+	argon2Params.Parallelism = cfg.Argon2Parallelism
+	hasher := passwords.NewArgon2idHasher(argon2Params)
+
+	users := store.NewUserStore(conn, hasher)
+	tokenStore := auth.NewRedisTokenStore(redisClient)
+	issuer := auth.NewIssuer([]byte(cfg.JWTAccessSecret), []byte(cfg.JWTRefreshSecret), cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.MFAPendingTTL, tokenStore)
+	verifier := auth.NewVerifier(issuer, tokenStore)
+	authHandlers := auth.NewHandlers(issuer, users, hasher)
+	userHandlers := handlers.NewUserHandlers(users, cfg.DefaultPhoneRegion, hasher)
+	twoFactorHandlers := twofactor.NewHandlers(users, issuer, hasher, cfg.TOTPEncryptionKey, "usermanagement")
+
+	var ipLimiter, subjectLimiter ratelimit.Limiter
+	var failures ratelimit.FailureTracker
+	switch cfg.RateLimitBackend {
+	case "redis":
+		ipLimiter = ratelimit.NewRedisLimiter(redisClient)
+		subjectLimiter = ratelimit.NewRedisLimiter(redisClient)
+		failures = ratelimit.NewRedisFailureTracker(redisClient, cfg.RateLimitLockoutThreshold, cfg.RateLimitLockoutWindow, cfg.RateLimitBackoffBase)
+	default:
+		ipLimiter = ratelimit.NewMemoryLimiter()
+		subjectLimiter = ratelimit.NewMemoryLimiter()
+		failures = ratelimit.NewMemoryFailureTracker(cfg.RateLimitLockoutThreshold, cfg.RateLimitLockoutWindow, cfg.RateLimitBackoffBase)
+	}
+	metrics := ratelimit.NewMetrics()
+	guard := ratelimit.NewGuard(ipLimiter, subjectLimiter, failures, users, metrics, cfg.RateLimitPerIPPerMinute, cfg.RateLimitPerSubjectPerMinute)
+
+	var spnegoMiddleware gin.HandlerFunc
+	if cfg.KerberosKeytabPath != "" {
+		spnegoMiddleware, err = auth.SPNEGO(auth.SPNEGOConfig{
+			KeytabPath:        cfg.KerberosKeytabPath,
+			ServicePrincipal:  cfg.KerberosServicePrincipal,
+			RealmEmailDomains: cfg.KerberosRealmEmailDomains,
+		}, users)
+		if err != nil {
+			log.Fatalf("auth: %v", err)
+		}
+	}
+
+	r := router.New(authHandlers, verifier, userHandlers, twoFactorHandlers, guard, metrics, spnegoMiddleware)
+	if err := r.Run(); err != nil {
+		log.Fatalf("server: %v", err)
+	}
+}
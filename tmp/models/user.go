@@ -0,0 +1,27 @@
+// Package models defines the persistent data types for usermanagement.
+package models
+
+import "time"
+
+// User is a registered account.
+type User struct {
+	ID            string    `json:"id" db:"id"`
+	Email         string    `json:"email" db:"email"`
+	PasswordHash  string    `json:"-" db:"password_hash"`
+	PhoneE164     *string   `json:"phone_e164,omitempty" db:"phone_e164"`
+	PhoneNational *string   `json:"phone_national,omitempty" db:"-"`
+	PhoneRegion   *string   `json:"phone_region,omitempty" db:"-"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// TOTPEnabled reports whether the user must supply a TOTP code (or
+	// recovery code) to complete login.
+	TOTPEnabled bool `json:"-" db:"totp_enabled"`
+	// TOTPSecretEncrypted is the user's TOTP secret, AES-GCM-encrypted
+	// at rest. Nil until /2fa/enroll is called.
+	TOTPSecretEncrypted *string `json:"-" db:"totp_secret_encrypted"`
+	// TOTPLastStep is the counter of the last TOTP step accepted for
+	// this user, so a code can't be replayed within the drift window
+	// that produced it.
+	TOTPLastStep int64 `json:"-" db:"totp_last_step"`
+}
@@ -0,0 +1,111 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idParams controls the cost of an Argon2id hash.
+type Argon2idParams struct {
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLen     uint32
+	KeyLen      uint32
+}
+
+// DefaultArgon2idParams returns the package's recommended Argon2id
+// parameters, tuned for an interactive login path rather than a
+// specific deploy host. See BenchmarkArgon2idHash to pick tighter
+// parameters for a given host.
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		MemoryKiB:   64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLen:     16,
+		KeyLen:      32,
+	}
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher builds a Hasher that produces and verifies Argon2id
+// hashes with the given parameters.
+func NewArgon2idHasher(params Argon2idParams) Hasher {
+	return &argon2idHasher{params: params}
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("passwords: generate salt: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.MemoryKiB, h.params.Parallelism, h.params.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.MemoryKiB, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, hash, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.MemoryKiB, params.Parallelism, uint32(len(hash)))
+	return subtle.ConstantTimeCompare(candidate, hash) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.MemoryKiB < h.params.MemoryKiB ||
+		params.Iterations < h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism
+}
+
+// decodeArgon2id parses a PHC-style Argon2id hash of the form
+// "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>".
+func decodeArgon2id(encoded string) (params Argon2idParams, salt, hash []byte, err error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 6 || fields[0] != "" || fields[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("passwords: malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("passwords: malformed argon2id version")
+	}
+	if version != argon2.Version {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("passwords: unsupported argon2id version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.MemoryKiB, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("passwords: malformed argon2id parameters")
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("passwords: malformed argon2id salt: %w", err)
+	}
+	hash, err = base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, fmt.Errorf("passwords: malformed argon2id hash: %w", err)
+	}
+
+	return params, salt, hash, nil
+}
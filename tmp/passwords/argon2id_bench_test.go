@@ -0,0 +1,35 @@
+package passwords
+
+import "testing"
+
+// These benchmarks exist so operators can size Argon2idParams for a
+// given deploy host: run `go test -bench Argon2idHash -benchtime=3x` and
+// pick the memory/iteration combination that lands near the target
+// per-login latency (typically 200-500ms for an interactive login).
+
+func BenchmarkArgon2idHash(b *testing.B) {
+	h := NewArgon2idHasher(DefaultArgon2idParams())
+	for i := 0; i < b.N; i++ {
+		if _, err := h.Hash("correct horse battery staple"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkArgon2idHashLowMemory(b *testing.B) {
+	h := NewArgon2idHasher(Argon2idParams{MemoryKiB: 16 * 1024, Iterations: 2, Parallelism: 1, SaltLen: 16, KeyLen: 32})
+	for i := 0; i < b.N; i++ {
+		if _, err := h.Hash("correct horse battery staple"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkArgon2idHashHighMemory(b *testing.B) {
+	h := NewArgon2idHasher(Argon2idParams{MemoryKiB: 256 * 1024, Iterations: 4, Parallelism: 4, SaltLen: 16, KeyLen: 32})
+	for i := 0; i < b.N; i++ {
+		if _, err := h.Hash("correct horse battery staple"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package passwords
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is used for new bcrypt hashes and as the rehash
+// threshold when bcrypt is the current policy.
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher builds a Hasher backed by bcrypt at the given cost.
+// Kept for accounts hashed before Argon2id became the default; not
+// used for new hashes unless explicitly selected as policy.
+func NewBcryptHasher(cost int) Hasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("passwords: bcrypt hash: %w", err)
+	}
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("passwords: bcrypt verify: %w", err)
+	}
+	return true, nil
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+	return cost < h.cost
+}
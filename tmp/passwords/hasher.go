@@ -0,0 +1,57 @@
+// Package passwords hashes and verifies user passwords behind a
+// pluggable Hasher interface, encoding each algorithm's parameters into
+// the stored hash so they can be tuned over time without invalidating
+// existing rows.
+package passwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hasher hashes and verifies passwords for one specific algorithm.
+type Hasher interface {
+	// Hash produces a new encoded hash for password using this hasher's
+	// current parameters.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded, which must have
+	// been produced by this same algorithm.
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash reports whether encoded was produced with weaker
+	// parameters than this hasher's current policy (or by a different
+	// algorithm entirely), meaning it should be replaced on next
+	// successful login.
+	NeedsRehash(encoded string) bool
+}
+
+// Identify returns the Hasher capable of verifying encoded, selected by
+// its encoded algorithm identifier.
+func Identify(encoded string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return NewArgon2idHasher(DefaultArgon2idParams()), nil
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return NewBcryptHasher(DefaultBcryptCost), nil
+	case strings.HasPrefix(encoded, "$scrypt$"):
+		return NewScryptHasher(DefaultScryptParams()), nil
+	default:
+		return nil, fmt.Errorf("passwords: unrecognized hash format")
+	}
+}
+
+// VerifyAndCheckRehash verifies password against encoded using whichever
+// algorithm produced it, and separately reports whether current's
+// policy considers encoded weak enough to warrant rehashing.
+func VerifyAndCheckRehash(encoded, password string, current Hasher) (ok, needsRehash bool, err error) {
+	hasher, err := Identify(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	ok, err = hasher.Verify(encoded, password)
+	if err != nil || !ok {
+		return ok, false, err
+	}
+
+	return true, current.NeedsRehash(encoded), nil
+}
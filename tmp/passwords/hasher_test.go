@@ -0,0 +1,99 @@
+package passwords
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgon2idHashAndVerify(t *testing.T) {
+	h := NewArgon2idHasher(DefaultArgon2idParams())
+
+	encoded, err := h.Hash("correct horse battery staple")
+	require.NoError(t, err)
+
+	ok, err := h.Verify(encoded, "correct horse battery staple")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(encoded, "wrong password")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestArgon2idNeedsRehashOnWeakerParams(t *testing.T) {
+	weak := NewArgon2idHasher(Argon2idParams{MemoryKiB: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLen: 16, KeyLen: 32})
+	encoded, err := weak.Hash("hunter2")
+	require.NoError(t, err)
+
+	strong := NewArgon2idHasher(DefaultArgon2idParams())
+	assert.True(t, strong.NeedsRehash(encoded))
+	assert.False(t, weak.NeedsRehash(encoded))
+}
+
+func TestBcryptHashAndVerify(t *testing.T) {
+	h := NewBcryptHasher(4)
+
+	encoded, err := h.Hash("hunter2")
+	require.NoError(t, err)
+
+	ok, err := h.Verify(encoded, "hunter2")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestScryptHashAndVerify(t *testing.T) {
+	h := NewScryptHasher(ScryptParams{LogN: 10, R: 8, P: 1, SaltLen: 16, KeyLen: 32})
+
+	encoded, err := h.Hash("hunter2")
+	require.NoError(t, err)
+
+	ok, err := h.Verify(encoded, "hunter2")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(encoded, "nope")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestIdentifyDispatchesByAlgorithm(t *testing.T) {
+	argon2Hash, err := NewArgon2idHasher(DefaultArgon2idParams()).Hash("hunter2")
+	require.NoError(t, err)
+	bcryptHash, err := NewBcryptHasher(4).Hash("hunter2")
+	require.NoError(t, err)
+	scryptHash, err := NewScryptHasher(ScryptParams{LogN: 10, R: 8, P: 1, SaltLen: 16, KeyLen: 32}).Hash("hunter2")
+	require.NoError(t, err)
+
+	for _, encoded := range []string{argon2Hash, bcryptHash, scryptHash} {
+		hasher, err := Identify(encoded)
+		require.NoError(t, err)
+
+		ok, err := hasher.Verify(encoded, "hunter2")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestVerifyAndCheckRehashUpgradesLegacyBcrypt(t *testing.T) {
+	legacy, err := NewBcryptHasher(4).Hash("hunter2")
+	require.NoError(t, err)
+
+	current := NewArgon2idHasher(DefaultArgon2idParams())
+
+	ok, needsRehash, err := VerifyAndCheckRehash(legacy, "hunter2", current)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+}
+
+func TestVerifyAndCheckRehashRejectsWrongPassword(t *testing.T) {
+	encoded, err := NewArgon2idHasher(DefaultArgon2idParams()).Hash("hunter2")
+	require.NoError(t, err)
+
+	ok, needsRehash, err := VerifyAndCheckRehash(encoded, "wrong", NewArgon2idHasher(DefaultArgon2idParams()))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, needsRehash)
+}
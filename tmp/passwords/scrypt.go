@@ -0,0 +1,98 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptParams controls the cost of a scrypt hash.
+type ScryptParams struct {
+	LogN    uint8 // CPU/memory cost as a power of two, i.e. N = 1<<LogN
+	R       int   // block size
+	P       int   // parallelism
+	SaltLen int
+	KeyLen  int
+}
+
+// DefaultScryptParams returns the package's recommended scrypt
+// parameters.
+func DefaultScryptParams() ScryptParams {
+	return ScryptParams{LogN: 15, R: 8, P: 1, SaltLen: 16, KeyLen: 32}
+}
+
+type scryptHasher struct {
+	params ScryptParams
+}
+
+// NewScryptHasher builds a Hasher backed by scrypt with the given
+// parameters.
+func NewScryptHasher(params ScryptParams) Hasher {
+	return &scryptHasher{params: params}
+}
+
+func (h *scryptHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("passwords: generate salt: %w", err)
+	}
+
+	hash, err := scrypt.Key([]byte(password), salt, 1<<h.params.LogN, h.params.R, h.params.P, h.params.KeyLen)
+	if err != nil {
+		return "", fmt.Errorf("passwords: scrypt hash: %w", err)
+	}
+
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		h.params.LogN, h.params.R, h.params.P,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+func (h *scryptHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, hash, err := decodeScrypt(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	candidate, err := scrypt.Key([]byte(password), salt, 1<<params.LogN, params.R, params.P, len(hash))
+	if err != nil {
+		return false, fmt.Errorf("passwords: scrypt verify: %w", err)
+	}
+	return subtle.ConstantTimeCompare(candidate, hash) == 1, nil
+}
+
+func (h *scryptHasher) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeScrypt(encoded)
+	if err != nil {
+		return true
+	}
+	return params.LogN < h.params.LogN || params.R != h.params.R || params.P != h.params.P
+}
+
+// decodeScrypt parses a hash of the form "$scrypt$ln=15,r=8,p=1$<salt>$<hash>".
+func decodeScrypt(encoded string) (params ScryptParams, salt, hash []byte, err error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 || fields[0] != "" || fields[1] != "scrypt" {
+		return ScryptParams{}, nil, nil, fmt.Errorf("passwords: malformed scrypt hash")
+	}
+
+	if _, err := fmt.Sscanf(fields[2], "ln=%d,r=%d,p=%d", &params.LogN, &params.R, &params.P); err != nil {
+		return ScryptParams{}, nil, nil, fmt.Errorf("passwords: malformed scrypt parameters")
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(fields[3])
+	if err != nil {
+		return ScryptParams{}, nil, nil, fmt.Errorf("passwords: malformed scrypt salt: %w", err)
+	}
+	hash, err = base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return ScryptParams{}, nil, nil, fmt.Errorf("passwords: malformed scrypt hash: %w", err)
+	}
+
+	return params, salt, hash, nil
+}
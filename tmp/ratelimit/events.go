@@ -0,0 +1,28 @@
+package ratelimit
+
+import "context"
+
+// EventKind labels the kind of auth_events row an audit entry records.
+type EventKind string
+
+const (
+	// EventFailure marks a single failed attempt for a subject.
+	EventFailure EventKind = "failure"
+	// EventLockout marks the failure that pushed a subject over its
+	// lockout threshold.
+	EventLockout EventKind = "lockout"
+)
+
+// Event is one row recorded for audit when a guarded request fails.
+type Event struct {
+	Subject string
+	IP      string
+	Route   string
+	Kind    EventKind
+}
+
+// EventRecorder persists Events for audit and alerting on
+// credential-stuffing attempts. Satisfied by *store.UserStore.
+type EventRecorder interface {
+	RecordAuthEvent(ctx context.Context, event Event) error
+}
@@ -0,0 +1,107 @@
+// Package ratelimit throttles and locks out abusive traffic to the
+// auth-adjacent endpoints, and records audit events and metrics for it.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+// Limiter caps how many requests a key may make within a window.
+type Limiter interface {
+	// Allow records an attempt for key and reports whether it is
+	// within the limit of n requests per window.
+	Allow(ctx context.Context, key string, n int, window time.Duration) (bool, error)
+}
+
+// memoryLimiterIdleTTL is how long a key's bucket may sit unused before
+// memoryLimiter's janitor reclaims it, so a stream of distinct keys
+// (e.g. per-token subjects) doesn't grow the map without bound.
+const memoryLimiterIdleTTL = 10 * time.Minute
+
+// memoryLimiter is an in-memory, per-key token bucket Limiter. It only
+// coordinates within a single process, so it's suitable for a
+// single-instance deployment.
+type memoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryLimiterBucket
+}
+
+type memoryLimiterBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewMemoryLimiter builds a Limiter backed by in-process token buckets.
+func NewMemoryLimiter() Limiter {
+	l := &memoryLimiter{buckets: make(map[string]*memoryLimiterBucket)}
+	go l.runJanitor()
+	return l
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string, n int, window time.Duration) (bool, error) {
+	return l.bucketFor(key, n, window).Allow(), nil
+}
+
+func (l *memoryLimiter) bucketFor(key string, n int, window time.Duration) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &memoryLimiterBucket{limiter: rate.NewLimiter(rate.Limit(float64(n)/window.Seconds()), n)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = time.Now()
+	return b.limiter
+}
+
+// runJanitor periodically evicts buckets that haven't been touched in
+// memoryLimiterIdleTTL. It runs for the lifetime of the process.
+func (l *memoryLimiter) runJanitor() {
+	ticker := time.NewTicker(memoryLimiterIdleTTL)
+	defer ticker.Stop()
+	for range ticker.C {
+		cutoff := time.Now().Add(-memoryLimiterIdleTTL)
+		l.mu.Lock()
+		for key, b := range l.buckets {
+			if b.lastSeen.Before(cutoff) {
+				delete(l.buckets, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// redisLimiter is a Redis-backed fixed-window Limiter, shared across
+// every instance of the service.
+type redisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter builds a Limiter backed by the given Redis client.
+func NewRedisLimiter(client *redis.Client) Limiter {
+	return &redisLimiter{client: client}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, n int, window time.Duration) (bool, error) {
+	fullKey := limiterKey(key)
+	count, err := l.client.Incr(ctx, fullKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("ratelimit: allow: %w", err)
+	}
+	if count == 1 {
+		if err := l.client.Expire(ctx, fullKey, window).Err(); err != nil {
+			return false, fmt.Errorf("ratelimit: allow: %w", err)
+		}
+	}
+	return count <= int64(n), nil
+}
+
+func limiterKey(key string) string {
+	return fmt.Sprintf("ratelimit:requests:%s", key)
+}
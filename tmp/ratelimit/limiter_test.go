@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiterAllowsUpToN(t *testing.T) {
+	l := NewMemoryLimiter()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		allowed, err := l.Allow(ctx, "k", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, allowed, "attempt %d should be allowed", i)
+	}
+
+	allowed, err := l.Allow(ctx, "k", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestMemoryLimiterTracksKeysIndependently(t *testing.T) {
+	l := NewMemoryLimiter()
+	ctx := context.Background()
+
+	allowed, err := l.Allow(ctx, "a", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = l.Allow(ctx, "b", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed, "a different key should have its own budget")
+}
+
+func TestMemoryFailureTrackerLocksOutAfterThreshold(t *testing.T) {
+	tr := NewMemoryFailureTracker(3, time.Minute, 0)
+	ctx := context.Background()
+
+	var lockedOut bool
+	var err error
+	for i := 0; i < 3; i++ {
+		lockedOut, err = tr.RecordFailure(ctx, "user@example.com")
+		require.NoError(t, err)
+	}
+	assert.True(t, lockedOut)
+
+	blocked, _, err := tr.Status(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+}
+
+func TestMemoryFailureTrackerBacksOffBeforeThreshold(t *testing.T) {
+	tr := NewMemoryFailureTracker(5, time.Minute, time.Hour)
+	ctx := context.Background()
+
+	lockedOut, err := tr.RecordFailure(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.False(t, lockedOut)
+
+	blocked, retryAfter, err := tr.Status(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.True(t, blocked, "should be backed off before the next attempt")
+	assert.Greater(t, retryAfter, time.Duration(0))
+}
+
+func TestMemoryFailureTrackerRecordSuccessClearsHistory(t *testing.T) {
+	tr := NewMemoryFailureTracker(3, time.Minute, time.Hour)
+	ctx := context.Background()
+
+	_, err := tr.RecordFailure(ctx, "user@example.com")
+	require.NoError(t, err)
+
+	blocked, _, err := tr.Status(ctx, "user@example.com")
+	require.NoError(t, err)
+	require.True(t, blocked, "precondition: should be backed off before clearing")
+
+	require.NoError(t, tr.RecordSuccess(ctx, "user@example.com"))
+
+	blocked, _, err = tr.Status(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+}
@@ -0,0 +1,210 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FailureTracker records authentication failures per subject (e.g. an
+// email address, or another per-identity key) and decides when that
+// subject should back off or be hard-locked-out, per a threshold/window
+// policy.
+type FailureTracker interface {
+	// RecordFailure records a failed attempt for subject and reports
+	// whether it has now reached the lockout threshold within the
+	// tracker's window.
+	RecordFailure(ctx context.Context, subject string) (lockedOut bool, err error)
+	// RecordSuccess clears subject's failure history, e.g. after a
+	// successful login.
+	RecordSuccess(ctx context.Context, subject string) error
+	// Status reports whether subject is currently blocked from
+	// attempting again, and if so how long until it may retry. A
+	// hard lockout blocks until the failure window expires and is
+	// reported with a retryAfter of 0; otherwise a request is
+	// blocked only until its exponential backoff since the last
+	// failure elapses, and retryAfter is the time remaining.
+	Status(ctx context.Context, subject string) (blocked bool, retryAfter time.Duration, err error)
+}
+
+// memoryFailureTracker is an in-memory FailureTracker, suitable for a
+// single-instance deployment.
+type memoryFailureTracker struct {
+	mu          sync.Mutex
+	threshold   int
+	window      time.Duration
+	backoffBase time.Duration
+	failures    map[string][]time.Time
+}
+
+// NewMemoryFailureTracker builds a FailureTracker that locks a subject
+// out once it has threshold failures within window, and otherwise backs
+// off for backoffBase*2^(n-1) after its nth failure.
+func NewMemoryFailureTracker(threshold int, window, backoffBase time.Duration) FailureTracker {
+	t := &memoryFailureTracker{
+		threshold:   threshold,
+		window:      window,
+		backoffBase: backoffBase,
+		failures:    make(map[string][]time.Time),
+	}
+	go t.runJanitor()
+	return t
+}
+
+// runJanitor periodically drops subjects whose failures have all aged
+// out of the window, so a stream of distinct subjects (e.g. failed
+// guesses against many different accounts) doesn't grow the map
+// without bound.
+func (t *memoryFailureTracker) runJanitor() {
+	interval := t.window
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		t.mu.Lock()
+		for subject := range t.failures {
+			if kept := t.withinWindow(subject, now); len(kept) == 0 {
+				delete(t.failures, subject)
+			} else {
+				t.failures[subject] = kept
+			}
+		}
+		t.mu.Unlock()
+	}
+}
+
+func (t *memoryFailureTracker) RecordFailure(ctx context.Context, subject string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	kept := t.withinWindow(subject, time.Now())
+	kept = append(kept, time.Now())
+	t.failures[subject] = kept
+	return len(kept) >= t.threshold, nil
+}
+
+func (t *memoryFailureTracker) RecordSuccess(ctx context.Context, subject string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, subject)
+	return nil
+}
+
+func (t *memoryFailureTracker) Status(ctx context.Context, subject string) (bool, time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	kept := t.withinWindow(subject, now)
+	count := len(kept)
+	if count == 0 {
+		delete(t.failures, subject)
+		return false, 0, nil
+	}
+	t.failures[subject] = kept
+
+	if count >= t.threshold {
+		return true, 0, nil
+	}
+
+	backoff := t.backoffBase * time.Duration(int64(1)<<uint(count-1))
+	wait := backoff - now.Sub(kept[count-1])
+	if wait > 0 {
+		return true, wait, nil
+	}
+	return false, 0, nil
+}
+
+// withinWindow returns subject's recorded failure times that are still
+// within the tracker's window as of now, discarding the rest. Caller
+// must hold t.mu.
+func (t *memoryFailureTracker) withinWindow(subject string, now time.Time) []time.Time {
+	cutoff := now.Add(-t.window)
+	var kept []time.Time
+	for _, at := range t.failures[subject] {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	return kept
+}
+
+// redisFailureTracker is a Redis-backed FailureTracker, shared across
+// every instance of the service.
+type redisFailureTracker struct {
+	client      *redis.Client
+	threshold   int
+	window      time.Duration
+	backoffBase time.Duration
+}
+
+// NewRedisFailureTracker builds a FailureTracker backed by the given
+// Redis client, with the same threshold/window/backoffBase policy as
+// NewMemoryFailureTracker.
+func NewRedisFailureTracker(client *redis.Client, threshold int, window, backoffBase time.Duration) FailureTracker {
+	return &redisFailureTracker{client: client, threshold: threshold, window: window, backoffBase: backoffBase}
+}
+
+func (t *redisFailureTracker) RecordFailure(ctx context.Context, subject string) (bool, error) {
+	countKey := failureCountKey(subject)
+	count, err := t.client.Incr(ctx, countKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("ratelimit: record failure: %w", err)
+	}
+	if count == 1 {
+		if err := t.client.Expire(ctx, countKey, t.window).Err(); err != nil {
+			return false, fmt.Errorf("ratelimit: record failure: %w", err)
+		}
+	}
+	if err := t.client.Set(ctx, failureLastKey(subject), time.Now().Unix(), t.window).Err(); err != nil {
+		return false, fmt.Errorf("ratelimit: record failure: %w", err)
+	}
+	return count >= int64(t.threshold), nil
+}
+
+func (t *redisFailureTracker) RecordSuccess(ctx context.Context, subject string) error {
+	if err := t.client.Del(ctx, failureCountKey(subject), failureLastKey(subject)).Err(); err != nil {
+		return fmt.Errorf("ratelimit: record success: %w", err)
+	}
+	return nil
+}
+
+func (t *redisFailureTracker) Status(ctx context.Context, subject string) (bool, time.Duration, error) {
+	count, err := t.client.Get(ctx, failureCountKey(subject)).Int64()
+	if err == redis.Nil {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("ratelimit: status: %w", err)
+	}
+	if count >= int64(t.threshold) {
+		return true, 0, nil
+	}
+
+	lastUnix, err := t.client.Get(ctx, failureLastKey(subject)).Int64()
+	if err == redis.Nil {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("ratelimit: status: %w", err)
+	}
+
+	backoff := t.backoffBase * time.Duration(int64(1)<<uint(count-1))
+	wait := backoff - time.Since(time.Unix(lastUnix, 0))
+	if wait > 0 {
+		return true, wait, nil
+	}
+	return false, 0, nil
+}
+
+func failureCountKey(subject string) string {
+	return fmt.Sprintf("ratelimit:failures:%s:count", subject)
+}
+
+func failureLastKey(subject string) string {
+	return fmt.Sprintf("ratelimit:failures:%s:last", subject)
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics tracks the auth_failures_total and auth_lockouts_total
+// counters exposed by Handler, labelled by route. Safe for concurrent
+// use.
+type Metrics struct {
+	mu       sync.Mutex
+	failures map[string]*int64
+	lockouts map[string]*int64
+}
+
+// NewMetrics builds an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		failures: make(map[string]*int64),
+		lockouts: make(map[string]*int64),
+	}
+}
+
+// IncFailures increments auth_failures_total for route.
+func (m *Metrics) IncFailures(route string) {
+	atomic.AddInt64(m.counter(m.failures, route), 1)
+}
+
+// IncLockouts increments auth_lockouts_total for route.
+func (m *Metrics) IncLockouts(route string) {
+	atomic.AddInt64(m.counter(m.lockouts, route), 1)
+}
+
+func (m *Metrics) counter(counters map[string]*int64, route string) *int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := counters[route]
+	if !ok {
+		c = new(int64)
+		counters[route] = c
+	}
+	return c
+}
+
+// Handler renders the counters in Prometheus text exposition format,
+// for a GET /metrics route.
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var b strings.Builder
+		b.WriteString("# HELP auth_failures_total Authentication failures, by route.\n")
+		b.WriteString("# TYPE auth_failures_total counter\n")
+		m.writeCounters(&b, "auth_failures_total", m.failures)
+		b.WriteString("# HELP auth_lockouts_total Hard lockouts triggered, by route.\n")
+		b.WriteString("# TYPE auth_lockouts_total counter\n")
+		m.writeCounters(&b, "auth_lockouts_total", m.lockouts)
+		c.String(http.StatusOK, b.String())
+	}
+}
+
+func (m *Metrics) writeCounters(b *strings.Builder, name string, counters map[string]*int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for route, c := range counters {
+		fmt.Fprintf(b, "%s{route=%q} %d\n", name, route, atomic.LoadInt64(c))
+	}
+}
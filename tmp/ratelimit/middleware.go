@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Guard enforces per-IP and per-subject request limits on a route,
+// plus exponential backoff and hard lockout on repeated failures for
+// the same subject, recording an audit event and metrics on each
+// failure.
+type Guard struct {
+	ipLimiter           Limiter
+	subjectLimiter      Limiter
+	failures            FailureTracker
+	events              EventRecorder
+	metrics             *Metrics
+	perIPPerMinute      int
+	perSubjectPerMinute int
+}
+
+// NewGuard builds a Guard. perIPPerMinute and perSubjectPerMinute bound
+// how many requests a single IP, respectively subject, may make per
+// route per minute before ipLimiter/subjectLimiter start rejecting them.
+func NewGuard(ipLimiter, subjectLimiter Limiter, failures FailureTracker, events EventRecorder, metrics *Metrics, perIPPerMinute, perSubjectPerMinute int) *Guard {
+	return &Guard{
+		ipLimiter:           ipLimiter,
+		subjectLimiter:      subjectLimiter,
+		failures:            failures,
+		events:              events,
+		metrics:             metrics,
+		perIPPerMinute:      perIPPerMinute,
+		perSubjectPerMinute: perSubjectPerMinute,
+	}
+}
+
+// Middleware builds a gin.HandlerFunc for route, identifying the
+// request's subject with subjectFn (nil disables per-subject limiting
+// and lockout, leaving only the per-IP limit).
+func (g *Guard) Middleware(route string, subjectFn SubjectFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		ip := c.ClientIP()
+
+		allowed, err := g.ipLimiter.Allow(ctx, route+":ip:"+ip, g.perIPPerMinute, time.Minute)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "rate limit check failed"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			c.Abort()
+			return
+		}
+
+		var subject string
+		var hasSubject bool
+		if subjectFn != nil {
+			subject, hasSubject = subjectFn(c)
+		}
+
+		if hasSubject {
+			blocked, retryAfter, err := g.failures.Status(ctx, subject)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "rate limit check failed"})
+				c.Abort()
+				return
+			}
+			if blocked {
+				if retryAfter > 0 {
+					c.Header("Retry-After", formatRetryAfter(retryAfter))
+				}
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed attempts; try again later"})
+				c.Abort()
+				return
+			}
+
+			subjectAllowed, err := g.subjectLimiter.Allow(ctx, route+":subject:"+subject, g.perSubjectPerMinute, time.Minute)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "rate limit check failed"})
+				c.Abort()
+				return
+			}
+			if !subjectAllowed {
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+
+		if !hasSubject {
+			return
+		}
+		switch c.Writer.Status() {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			g.recordFailure(ctx, route, ip, subject)
+		case http.StatusOK:
+			if err := g.failures.RecordSuccess(ctx, subject); err != nil {
+				log.Printf("ratelimit: record success for subject on %s: %v", route, err)
+			}
+		}
+	}
+}
+
+func (g *Guard) recordFailure(ctx context.Context, route, ip, subject string) {
+	lockedOut, err := g.failures.RecordFailure(ctx, subject)
+	if err != nil {
+		log.Printf("ratelimit: record failure for subject on %s: %v", route, err)
+		return
+	}
+
+	kind := EventFailure
+	g.metrics.IncFailures(route)
+	if lockedOut {
+		kind = EventLockout
+		g.metrics.IncLockouts(route)
+	}
+
+	if err := g.events.RecordAuthEvent(ctx, Event{Subject: subject, IP: ip, Route: route, Kind: kind}); err != nil {
+		log.Printf("ratelimit: record auth event for subject on %s: %v", route, err)
+	}
+}
+
+func formatRetryAfter(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubjectFunc extracts the subject a request is acting on (a username,
+// or another per-identity key) for per-subject limiting and lockout. It
+// reports ok=false if no subject can be determined, in which case only
+// the per-IP limit applies.
+type SubjectFunc func(c *gin.Context) (subject string, ok bool)
+
+// LoginSubject extracts the "email" field from a login request body as
+// the per-subject key.
+func LoginSubject(c *gin.Context) (string, bool) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if !peekJSON(c, &body) || body.Email == "" {
+		return "", false
+	}
+	return body.Email, true
+}
+
+// TokenFieldSubject builds a SubjectFunc for routes authenticated by a
+// bearer-style token rather than a username (e.g. /refresh's
+// refresh_token): it hashes the named JSON
+// field so the token itself is never used as a lookup key or persisted
+// in the clear.
+func TokenFieldSubject(field string) SubjectFunc {
+	return func(c *gin.Context) (string, bool) {
+		var body map[string]string
+		if !peekJSON(c, &body) {
+			return "", false
+		}
+		token := body[field]
+		if token == "" {
+			return "", false
+		}
+		sum := sha256.Sum256([]byte(token))
+		return hex.EncodeToString(sum[:]), true
+	}
+}
+
+// JWTFieldSubject builds a SubjectFunc for routes that accept a JWT in
+// the named JSON field (e.g. /2fa/verify and /2fa/recover's mfa_token),
+// where brute-forcing targets a specific account rather than the token
+// itself: it keys on the token's embedded user ID so that repeated
+// attempts against the same account accumulate failures, even though a
+// fresh mfa_token is minted on every login attempt. The claim is read
+// without verifying the token's signature — it is only used for
+// rate-limit bookkeeping here, never for authorization, since the real
+// handler still verifies the token before acting on it.
+func JWTFieldSubject(field string) SubjectFunc {
+	return func(c *gin.Context) (string, bool) {
+		var body map[string]string
+		if !peekJSON(c, &body) {
+			return "", false
+		}
+		token := body[field]
+		if token == "" {
+			return "", false
+		}
+		userID, ok := unverifiedJWTUserID(token)
+		if !ok {
+			return "", false
+		}
+		return "uid:" + userID, true
+	}
+}
+
+// unverifiedJWTUserID extracts the "uid" claim from a JWT's payload
+// segment without verifying its signature.
+func unverifiedJWTUserID(token string) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", false
+	}
+	var claims struct {
+		UserID string `json:"uid"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UserID == "" {
+		return "", false
+	}
+	return claims.UserID, true
+}
+
+// peekJSON decodes the request body into v without consuming it, so
+// that a downstream c.ShouldBindJSON call in the real handler still
+// sees the full body.
+func peekJSON(c *gin.Context, v interface{}) bool {
+	if c.Request.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return false
+	}
+	return json.Unmarshal(body, v) == nil
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJWT builds a syntactically valid, unsigned-for-test-purposes JWT
+// whose payload carries the given user ID under the "uid" claim.
+func fakeJWT(userID string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"uid":%q}`, userID)))
+	return header + "." + payload + ".sig"
+}
+
+func newTestContext(body string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	return c, w
+}
+
+func TestLoginSubjectExtractsEmailAndPreservesBody(t *testing.T) {
+	c, _ := newTestContext(`{"email":"user@example.com","password":"hunter2"}`)
+
+	subject, ok := LoginSubject(c)
+	require.True(t, ok)
+	assert.Equal(t, "user@example.com", subject)
+
+	remaining, err := io.ReadAll(c.Request.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"email":"user@example.com","password":"hunter2"}`, string(remaining))
+}
+
+func TestLoginSubjectRejectsMissingEmail(t *testing.T) {
+	c, _ := newTestContext(`{"password":"hunter2"}`)
+
+	_, ok := LoginSubject(c)
+	assert.False(t, ok)
+}
+
+func TestTokenFieldSubjectHashesToken(t *testing.T) {
+	c, _ := newTestContext(`{"refresh_token":"abc123"}`)
+
+	subject, ok := TokenFieldSubject("refresh_token")(c)
+	require.True(t, ok)
+	assert.NotEqual(t, "abc123", subject)
+	assert.Len(t, subject, 64) // hex-encoded sha256
+}
+
+func TestTokenFieldSubjectRejectsMissingField(t *testing.T) {
+	c, _ := newTestContext(`{}`)
+
+	_, ok := TokenFieldSubject("refresh_token")(c)
+	assert.False(t, ok)
+}
+
+func TestJWTFieldSubjectKeysOnEmbeddedUserID(t *testing.T) {
+	c, _ := newTestContext(fmt.Sprintf(`{"mfa_token":%q}`, fakeJWT("user-1")))
+
+	subject, ok := JWTFieldSubject("mfa_token")(c)
+	require.True(t, ok)
+	assert.Equal(t, "uid:user-1", subject)
+}
+
+func TestJWTFieldSubjectIsStableAcrossDistinctTokensForSameUser(t *testing.T) {
+	first, _ := newTestContext(fmt.Sprintf(`{"mfa_token":%q}`, fakeJWT("user-1")))
+	second, _ := newTestContext(fmt.Sprintf(`{"mfa_token":%q}`, fakeJWT("user-1")))
+
+	a, ok := JWTFieldSubject("mfa_token")(first)
+	require.True(t, ok)
+	b, ok := JWTFieldSubject("mfa_token")(second)
+	require.True(t, ok)
+
+	assert.Equal(t, a, b, "two separately-issued tokens for the same user must share a lockout subject")
+}
+
+func TestJWTFieldSubjectRejectsMalformedToken(t *testing.T) {
+	c, _ := newTestContext(`{"mfa_token":"not-a-jwt"}`)
+
+	_, ok := JWTFieldSubject("mfa_token")(c)
+	assert.False(t, ok)
+}
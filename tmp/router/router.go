@@ -0,0 +1,40 @@
+// Package router wires HTTP routes to their handlers.
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"usermanagement/auth"
+	"usermanagement/handlers"
+	"usermanagement/ratelimit"
+	"usermanagement/twofactor"
+)
+
+// New builds the Gin engine with all routes registered. spnegoMiddleware
+// may be nil, in which case Kerberos SSO is disabled.
+func New(authHandlers *auth.Handlers, verifier *auth.Verifier, userHandlers *handlers.UserHandlers, twoFactorHandlers *twofactor.Handlers, guard *ratelimit.Guard, metrics *ratelimit.Metrics, spnegoMiddleware gin.HandlerFunc) *gin.Engine {
+	r := gin.Default()
+
+	r.GET("/metrics", metrics.Handler())
+
+	r.POST("/login", guard.Middleware("login", ratelimit.LoginSubject), authHandlers.Login)
+	r.POST("/refresh", guard.Middleware("refresh", ratelimit.TokenFieldSubject("refresh_token")), authHandlers.Refresh)
+	r.POST("/logout", verifier.Middleware(), authHandlers.Logout)
+	// NOTE: there is no password-reset endpoint in this tree yet, so it
+	// isn't guarded here. Wire it through guard.Middleware the same way
+	// as /login and /refresh once that flow exists.
+
+	if spnegoMiddleware != nil {
+		r.POST("/login/kerberos", spnegoMiddleware, authHandlers.LoginKerberos)
+	}
+
+	r.POST("/users", userHandlers.Register)
+	r.PATCH("/users/:id/contact", verifier.Middleware(), userHandlers.UpdateContact)
+
+	r.POST("/2fa/enroll", verifier.Middleware(), twoFactorHandlers.Enroll)
+	r.POST("/2fa/activate", verifier.Middleware(), twoFactorHandlers.Activate)
+	r.POST("/2fa/verify", guard.Middleware("2fa_verify", ratelimit.JWTFieldSubject("mfa_token")), twoFactorHandlers.Verify)
+	r.POST("/2fa/recover", guard.Middleware("2fa_recover", ratelimit.JWTFieldSubject("mfa_token")), twoFactorHandlers.Recover)
+
+	return r
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"usermanagement/ratelimit"
+)
+
+// RecordAuthEvent inserts an audit row for a rate-limited or
+// locked-out authentication attempt.
+func (s *UserStore) RecordAuthEvent(ctx context.Context, event ratelimit.Event) error {
+	const q = `INSERT INTO auth_events (id, subject, ip, route, kind) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := s.db.ExecContext(ctx, q, uuid.NewString(), event.Subject, event.IP, event.Route, string(event.Kind)); err != nil {
+		return fmt.Errorf("store: record auth event: %w", err)
+	}
+	return nil
+}
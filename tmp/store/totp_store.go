@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"usermanagement/passwords"
+)
+
+// SetTOTPSecret stores encryptedSecret as the pending TOTP secret for
+// id. totp_enabled stays false until ActivateTOTP confirms the user can
+// produce a valid code.
+func (s *UserStore) SetTOTPSecret(ctx context.Context, id, encryptedSecret string) error {
+	const q = `UPDATE users SET totp_secret_encrypted = $2, totp_enabled = false, updated_at = now() WHERE id = $1`
+	return s.execRowsAffected(ctx, q, id, encryptedSecret)
+}
+
+// ActivateTOTP flips totp_enabled on for id once they've proven
+// possession of the enrolled secret.
+func (s *UserStore) ActivateTOTP(ctx context.Context, id string) error {
+	const q = `UPDATE users SET totp_enabled = true, updated_at = now() WHERE id = $1 AND totp_secret_encrypted IS NOT NULL`
+	return s.execRowsAffected(ctx, q, id)
+}
+
+// InsertRecoveryCodes stores hashedCodes (each already hashed by the
+// caller's passwords.Hasher) as fresh, unused recovery codes for userID,
+// replacing any codes from a previous enrollment.
+func (s *UserStore) InsertRecoveryCodes(ctx context.Context, userID string, hashedCodes []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: insert recovery codes: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("store: insert recovery codes: %w", err)
+	}
+
+	const q = `INSERT INTO totp_recovery_codes (id, user_id, code_hash) VALUES ($1, $2, $3)`
+	for _, hash := range hashedCodes {
+		if _, err := tx.ExecContext(ctx, q, uuid.NewString(), userID, hash); err != nil {
+			return fmt.Errorf("store: insert recovery codes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: insert recovery codes: %w", err)
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode checks code against userID's unused recovery
+// codes using hasher, and atomically marks the matching one used so it
+// can never be replayed. It reports false, nil if no unused code
+// matches.
+func (s *UserStore) ConsumeRecoveryCode(ctx context.Context, userID, code string, hasher passwords.Hasher) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("store: consume recovery code: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, code_hash FROM totp_recovery_codes WHERE user_id = $1 AND used_at IS NULL FOR UPDATE`, userID)
+	if err != nil {
+		return false, fmt.Errorf("store: consume recovery code: %w", err)
+	}
+	type candidate struct{ id, hash string }
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.id, &c.hash); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("store: consume recovery code: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("store: consume recovery code: %w", err)
+	}
+
+	var matchID string
+	for _, c := range candidates {
+		ok, err := hasher.Verify(c.hash, code)
+		if err != nil {
+			return false, fmt.Errorf("store: consume recovery code: %w", err)
+		}
+		if ok {
+			matchID = c.id
+			break
+		}
+	}
+	if matchID == "" {
+		return false, nil
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE totp_recovery_codes SET used_at = now() WHERE id = $1 AND used_at IS NULL`, matchID)
+	if err != nil {
+		return false, fmt.Errorf("store: consume recovery code: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("store: consume recovery code: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("store: consume recovery code: %w", err)
+	}
+	return true, nil
+}
+
+func (s *UserStore) execRowsAffected(ctx context.Context, q string, args ...interface{}) error {
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package store contains database-backed repositories for persisted
+// domain types.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"usermanagement/contact"
+	"usermanagement/models"
+	"usermanagement/passwords"
+)
+
+// UserStore persists and retrieves user records.
+type UserStore struct {
+	db     *sql.DB
+	hasher passwords.Hasher
+}
+
+// NewUserStore builds a UserStore backed by db. hasher is used to hash
+// the placeholder password set on JIT-provisioned SSO accounts.
+func NewUserStore(db *sql.DB, hasher passwords.Hasher) *UserStore {
+	return &UserStore{db: db, hasher: hasher}
+}
+
+// Create inserts a new user with the given email and password hash.
+// phoneE164 may be nil if no phone number was supplied.
+func (s *UserStore) Create(ctx context.Context, email, passwordHash string, phoneE164 *string) (*models.User, error) {
+	u := &models.User{ID: uuid.NewString(), Email: email, PasswordHash: passwordHash, PhoneE164: phoneE164}
+	const q = `
+		INSERT INTO users (id, email, password_hash, phone_e164)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at`
+	if err := s.db.QueryRowContext(ctx, q, u.ID, u.Email, u.PasswordHash, u.PhoneE164).Scan(&u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("store: create user: %w", err)
+	}
+	return u, nil
+}
+
+// UpdateContact sets the E.164 phone number on a user record.
+func (s *UserStore) UpdateContact(ctx context.Context, id string, phoneE164 *string) (*models.User, error) {
+	const q = `
+		UPDATE users SET phone_e164 = $2, updated_at = now()
+		WHERE id = $1
+		RETURNING id, email, password_hash, phone_e164, totp_enabled, totp_secret_encrypted, totp_last_step, created_at, updated_at`
+	return s.scanUser(s.db.QueryRowContext(ctx, q, id, phoneE164))
+}
+
+// VerifyLogin authenticates email/password against the stored hash and,
+// if the hash was produced under a weaker policy than hasher's current
+// one, rehashes and persists it in the same transaction as the lookup.
+// A failure to rehash is logged but does not fail the login, since the
+// password has already been verified correct. It returns sql.ErrNoRows
+// for both "no such user" and "wrong password" so callers can't
+// distinguish account existence from a failed password check.
+func (s *UserStore) VerifyLogin(ctx context.Context, email, password string, hasher passwords.Hasher) (*models.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("store: verify login: %w", err)
+	}
+	defer tx.Rollback()
+
+	const q = `
+		SELECT id, email, password_hash, phone_e164, totp_enabled, totp_secret_encrypted, totp_last_step, created_at, updated_at
+		FROM users WHERE email = $1 FOR UPDATE`
+	user, err := s.scanUser(tx.QueryRowContext(ctx, q, email))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, needsRehash, err := passwords.VerifyAndCheckRehash(user.PasswordHash, password, hasher)
+	if err != nil {
+		return nil, fmt.Errorf("store: verify login: %w", err)
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	if needsRehash {
+		if hash, err := hasher.Hash(password); err != nil {
+			log.Printf("store: rehash user %s: %v", user.ID, err)
+		} else if _, err := tx.ExecContext(ctx, `SAVEPOINT rehash`); err != nil {
+			log.Printf("store: persist rehash for user %s: %v", user.ID, err)
+		} else if _, err := tx.ExecContext(ctx, `UPDATE users SET password_hash = $2, updated_at = now() WHERE id = $1`, user.ID, hash); err != nil {
+			log.Printf("store: persist rehash for user %s: %v", user.ID, err)
+			// A failed statement aborts the transaction; roll back to the
+			// savepoint so the login itself can still commit.
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT rehash`); err != nil {
+				log.Printf("store: roll back rehash for user %s: %v", user.ID, err)
+			}
+		} else {
+			user.PasswordHash = hash
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("store: verify login: %w", err)
+	}
+	return user, nil
+}
+
+// AdvanceTOTPStep atomically records step as the last-accepted TOTP
+// counter for id, succeeding only if step is newer than whatever is
+// already stored. Callers must treat a false result as a replayed code
+// and reject it, even though it matched the secret.
+func (s *UserStore) AdvanceTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	const q = `UPDATE users SET totp_last_step = $2, updated_at = now() WHERE id = $1 AND totp_last_step < $2`
+	res, err := s.db.ExecContext(ctx, q, id, step)
+	if err != nil {
+		return false, fmt.Errorf("store: advance totp step: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("store: advance totp step: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetByEmail looks up a user by their email address.
+func (s *UserStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	const q = `SELECT id, email, password_hash, phone_e164, totp_enabled, totp_secret_encrypted, totp_last_step, created_at, updated_at FROM users WHERE email = $1`
+	return s.scanUser(s.db.QueryRowContext(ctx, q, email))
+}
+
+// GetByID looks up a user by their primary key.
+func (s *UserStore) GetByID(ctx context.Context, id string) (*models.User, error) {
+	const q = `SELECT id, email, password_hash, phone_e164, totp_enabled, totp_secret_encrypted, totp_last_step, created_at, updated_at FROM users WHERE id = $1`
+	return s.scanUser(s.db.QueryRowContext(ctx, q, id))
+}
+
+// FindOrProvisionByEmail returns the user with the given email, creating
+// one just-in-time (with no usable password, since the account is
+// authenticated exclusively via SSO) if none exists yet.
+func (s *UserStore) FindOrProvisionByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.GetByEmail(ctx, email)
+	if err == nil {
+		return user, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(uuid.NewString())
+	if err != nil {
+		return nil, fmt.Errorf("store: provision user: %w", err)
+	}
+	return s.Create(ctx, email, hash, nil)
+}
+
+// GetByPhoneE164 looks up a user by their normalized phone number.
+func (s *UserStore) GetByPhoneE164(ctx context.Context, e164 string) (*models.User, error) {
+	const q = `SELECT id, email, password_hash, phone_e164, totp_enabled, totp_secret_encrypted, totp_last_step, created_at, updated_at FROM users WHERE phone_e164 = $1`
+	return s.scanUser(s.db.QueryRowContext(ctx, q, e164))
+}
+
+func (s *UserStore) scanUser(row *sql.Row) (*models.User, error) {
+	var u models.User
+	if err := row.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.PhoneE164, &u.TOTPEnabled, &u.TOTPSecretEncrypted, &u.TOTPLastStep, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("store: scan user: %w", err)
+	}
+	if u.PhoneE164 != nil {
+		if national, region, err := contact.Describe(*u.PhoneE164); err == nil {
+			u.PhoneNational, u.PhoneRegion = &national, &region
+		}
+	}
+	return &u, nil
+}
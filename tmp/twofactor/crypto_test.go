@@ -0,0 +1,48 @@
+package twofactor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecryptSecretRoundTrips(t *testing.T) {
+	key := make([]byte, 32)
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	encrypted, err := EncryptSecret(key, secret)
+	require.NoError(t, err)
+
+	decrypted, err := DecryptSecret(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, secret, decrypted)
+}
+
+func TestDecryptSecretRejectsWrongKey(t *testing.T) {
+	key := make([]byte, 32)
+	wrongKey := make([]byte, 32)
+	wrongKey[0] = 1
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	encrypted, err := EncryptSecret(key, secret)
+	require.NoError(t, err)
+
+	_, err = DecryptSecret(wrongKey, encrypted)
+	assert.Error(t, err)
+}
+
+func TestGenerateRecoveryCodesAreUniqueAndFormatted(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[A-Z2-7]{8}-[A-Z2-7]{8}$`, code)
+		assert.False(t, seen[code], "duplicate recovery code %q", code)
+		seen[code] = true
+	}
+}
@@ -0,0 +1,291 @@
+package twofactor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"usermanagement/auth"
+	"usermanagement/models"
+	"usermanagement/passwords"
+)
+
+// Store is the persistence interface these handlers depend on. It is
+// satisfied by *store.UserStore.
+type Store interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	SetTOTPSecret(ctx context.Context, id, encryptedSecret string) error
+	ActivateTOTP(ctx context.Context, id string) error
+	AdvanceTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+	InsertRecoveryCodes(ctx context.Context, userID string, hashedCodes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID, code string, hasher passwords.Hasher) (bool, error)
+}
+
+// Handlers exposes the 2FA enrollment/activation/verification endpoints.
+type Handlers struct {
+	users         Store
+	issuer        *auth.Issuer
+	hasher        passwords.Hasher
+	encryptionKey []byte
+	otpIssuer     string
+}
+
+// NewHandlers builds Handlers. encryptionKey is the AES-256 key used to
+// encrypt TOTP secrets at rest, and otpIssuer is the issuer name shown
+// in enrolled authenticator apps (e.g. "usermanagement").
+func NewHandlers(users Store, issuer *auth.Issuer, hasher passwords.Hasher, encryptionKey []byte, otpIssuer string) *Handlers {
+	return &Handlers{users: users, issuer: issuer, hasher: hasher, encryptionKey: encryptionKey, otpIssuer: otpIssuer}
+}
+
+type enrollRequest struct {
+	// Code is required to re-enroll a user who already has 2FA enabled,
+	// proving possession of the current secret before it's replaced.
+	// Ignored (and not required) for a user's first enrollment.
+	Code string `json:"code" binding:"omitempty,len=6,numeric"`
+}
+
+type enrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// Enroll generates a new TOTP secret for the authenticated user and
+// stores it, pending confirmation via Activate. Must run behind
+// Verifier.Middleware.
+func (h *Handlers) Enroll(c *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authentication"})
+		return
+	}
+
+	var req enrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.users.GetByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to look up user"})
+		return
+	}
+
+	if user.TOTPEnabled {
+		if req.Code == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "code is required to replace an active 2fa secret"})
+			return
+		}
+		if _, ok := h.verifyCode(c, user.ID, req.Code); !ok {
+			return
+		}
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+		return
+	}
+
+	encrypted, err := EncryptSecret(h.encryptionKey, secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encrypt secret"})
+		return
+	}
+
+	if err := h.users.SetTOTPSecret(c.Request.Context(), user.ID, encrypted); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollResponse{
+		Secret:     EncodeSecret(secret),
+		OTPAuthURL: BuildOTPAuthURI(h.otpIssuer, user.Email, secret),
+	})
+}
+
+type codeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type activateResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Activate confirms enrollment by checking a code against the pending
+// secret, flips the user's 2FA on, and issues one-time recovery codes.
+// Must run behind Verifier.Middleware.
+func (h *Handlers) Activate(c *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authentication"})
+		return
+	}
+
+	var req codeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.verifyCode(c, claims.UserID, req.Code)
+	if !ok {
+		return
+	}
+
+	if err := h.users.ActivateTOTP(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to activate 2fa"})
+		return
+	}
+
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+	hashed := make([]string, len(codes))
+	for i, code := range codes {
+		hash, err := h.hasher.Hash(code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash recovery codes"})
+			return
+		}
+		hashed[i] = hash
+	}
+	if err := h.users.InsertRecoveryCodes(c.Request.Context(), user.ID, hashed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, activateResponse{RecoveryCodes: codes})
+}
+
+type mfaTokenRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type verifyRequest struct {
+	mfaTokenRequest
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Verify completes a login for a user with 2FA enabled: mfa_token is
+// the pending-MFA token returned by auth.Handlers.Login, and code is
+// their current TOTP code.
+func (h *Handlers) Verify(c *gin.Context) {
+	var req verifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := h.verifyMFAToken(c, req.MFAToken)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.verifyCode(c, userID, req.Code); !ok {
+		return
+	}
+
+	h.issuePair(c, userID)
+}
+
+type recoverRequest struct {
+	mfaTokenRequest
+	RecoveryCode string `json:"recovery_code" binding:"required"`
+}
+
+// Recover completes a login using a single-use recovery code in place
+// of a TOTP code, for when the user has lost their authenticator.
+func (h *Handlers) Recover(c *gin.Context) {
+	var req recoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := h.verifyMFAToken(c, req.MFAToken)
+	if !ok {
+		return
+	}
+
+	ok, err := h.users.ConsumeRecoveryCode(c.Request.Context(), userID, req.RecoveryCode, h.hasher)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify recovery code"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or already-used recovery code"})
+		return
+	}
+
+	h.issuePair(c, userID)
+}
+
+// verifyMFAToken parses and validates an mfa-pending token, writing a
+// 401 response and returning ok=false on failure.
+func (h *Handlers) verifyMFAToken(c *gin.Context, token string) (userID string, ok bool) {
+	claims, err := h.issuer.VerifyMFAPending(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		return "", false
+	}
+	return claims.UserID, true
+}
+
+// verifyCode loads userID's enrolled TOTP secret and checks code against
+// it, rejecting any step at or before the last one accepted for this
+// user so a captured code can't be replayed within its drift window. It
+// writes an error response and returns ok=false on failure.
+func (h *Handlers) verifyCode(c *gin.Context, userID, code string) (*models.User, bool) {
+	user, err := h.users.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to look up user"})
+		return nil, false
+	}
+	if user.TOTPSecretEncrypted == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2fa is not enrolled"})
+		return nil, false
+	}
+
+	secret, err := DecryptSecret(h.encryptionKey, *user.TOTPSecretEncrypted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decrypt secret"})
+		return nil, false
+	}
+
+	ok, step := VerifyAfter(secret, code, time.Now(), uint64(user.TOTPLastStep))
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		return nil, false
+	}
+
+	advanced, err := h.users.AdvanceTOTPStep(c.Request.Context(), user.ID, int64(step))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record totp step"})
+		return nil, false
+	}
+	if !advanced {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *Handlers) issuePair(c *gin.Context, userID string) {
+	pair, err := h.issuer.IssuePair(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+	c.JSON(http.StatusOK, tokenResponse{AccessToken: pair.AccessToken, RefreshToken: pair.RefreshToken})
+}
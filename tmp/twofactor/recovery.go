@@ -0,0 +1,30 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+)
+
+// recoveryCodeCount is how many single-use recovery codes are generated
+// at enrollment.
+const recoveryCodeCount = 10
+
+// recoveryCodeBytes is the amount of randomness behind each code.
+const recoveryCodeBytes = 10
+
+// GenerateRecoveryCodes returns a fresh set of single-use recovery
+// codes, formatted as "xxxxxxxx-xxxxxxxx" (two groups of eight base32
+// characters) for readability.
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("twofactor: generate recovery code: %w", err)
+		}
+		encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+		codes[i] = fmt.Sprintf("%s-%s", encoded[:8], encoded[8:])
+	}
+	return codes, nil
+}
@@ -0,0 +1,41 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/url"
+)
+
+// secretLen is the size in bytes of a generated TOTP secret.
+const secretLen = 20
+
+// GenerateSecret returns a new random TOTP secret.
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, secretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("twofactor: generate secret: %w", err)
+	}
+	return secret, nil
+}
+
+// EncodeSecret renders secret as the base32 string users enter into
+// their authenticator app if they can't scan the QR code.
+func EncodeSecret(secret []byte) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+}
+
+// BuildOTPAuthURI builds the otpauth:// URI authenticator apps use to
+// enroll a TOTP secret, per the Key Uri Format used by Google
+// Authenticator and compatible apps.
+func BuildOTPAuthURI(issuer, accountName string, secret []byte) string {
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, accountName))
+	q := url.Values{
+		"secret":    {EncodeSecret(secret)},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(digits)},
+		"period":    {fmt.Sprint(int(stepPeriod.Seconds()))},
+	}
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, q.Encode())
+}
@@ -0,0 +1,83 @@
+// Package twofactor implements RFC 6238 TOTP two-factor authentication:
+// generating and verifying time-based one-time codes, encrypting secrets
+// at rest, and issuing single-use recovery codes.
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// stepPeriod is the RFC 6238 time step: a new code is valid every 30
+// seconds.
+const stepPeriod = 30 * time.Second
+
+// digits is the number of digits in a generated code, per RFC 4226 §5.3.
+const digits = 6
+
+// driftWindow is how many steps before and after the current one are
+// also accepted, to tolerate clock drift between client and server.
+const driftWindow = 1
+
+// Generate produces the 6-digit TOTP code for secret at time t.
+func Generate(secret []byte, t time.Time) string {
+	return generateAtCounter(secret, uint64(t.Unix())/uint64(stepPeriod.Seconds()))
+}
+
+// Verify reports whether code matches secret at time t, within a ±1 step
+// window to tolerate clock drift.
+func Verify(secret []byte, code string, t time.Time) bool {
+	ok, _ := VerifyAfter(secret, code, t, 0)
+	return ok
+}
+
+// VerifyAfter is like Verify, but also rejects any step at or before
+// lastStep, so a code that was already accepted can't be replayed for
+// the rest of its drift window. On success it returns the step counter
+// that matched, which callers must persist as the caller's new lastStep.
+func VerifyAfter(secret []byte, code string, t time.Time, lastStep uint64) (ok bool, step uint64) {
+	if len(code) != digits {
+		return false, 0
+	}
+	counter := uint64(t.Unix()) / uint64(stepPeriod.Seconds())
+	for delta := -driftWindow; delta <= driftWindow; delta++ {
+		s := counter
+		if delta < 0 && uint64(-delta) > s {
+			continue
+		}
+		s += uint64(delta)
+		if s <= lastStep {
+			continue
+		}
+		want := generateAtCounter(secret, s)
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return true, s
+		}
+	}
+	return false, 0
+}
+
+// generateAtCounter implements RFC 4226 HOTP (HMAC-SHA1 over an 8-byte
+// big-endian counter, dynamic truncation, modulo 10^digits), which RFC
+// 6238 TOTP layers a time-derived counter on top of.
+func generateAtCounter(secret []byte, counter uint64) string {
+	var counterBytes [8]byte
+	binary.BigEndian.PutUint64(counterBytes[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counterBytes[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, truncated%mod)
+}
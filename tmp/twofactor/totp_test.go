@@ -0,0 +1,72 @@
+package twofactor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the ASCII secret "12345678901234567890" used by the
+// SHA1 test vectors in RFC 6238 Appendix B.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestGenerateMatchesRFC6238Vectors(t *testing.T) {
+	cases := []struct {
+		unixTime int64
+		want     string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range cases {
+		got := Generate(rfc6238Secret, time.Unix(tc.unixTime, 0).UTC())
+		assert.Equal(t, tc.want, got, "unix time %d", tc.unixTime)
+	}
+}
+
+func TestVerifyAcceptsCurrentStep(t *testing.T) {
+	now := time.Unix(1111111111, 0).UTC()
+	code := Generate(rfc6238Secret, now)
+	assert.True(t, Verify(rfc6238Secret, code, now))
+}
+
+func TestVerifyToleratesClockDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0).UTC()
+	code := Generate(rfc6238Secret, now)
+
+	assert.True(t, Verify(rfc6238Secret, code, now.Add(stepPeriod)))
+	assert.True(t, Verify(rfc6238Secret, code, now.Add(-stepPeriod)))
+	assert.False(t, Verify(rfc6238Secret, code, now.Add(2*stepPeriod)))
+}
+
+func TestVerifyRejectsWrongCode(t *testing.T) {
+	now := time.Unix(1111111111, 0).UTC()
+	assert.False(t, Verify(rfc6238Secret, "000000", now))
+}
+
+func TestVerifyAfterRejectsReplayOfAcceptedStep(t *testing.T) {
+	now := time.Unix(1111111111, 0).UTC()
+	code := Generate(rfc6238Secret, now)
+
+	ok, step := VerifyAfter(rfc6238Secret, code, now, 0)
+	require.True(t, ok)
+
+	ok, _ = VerifyAfter(rfc6238Secret, code, now, step)
+	assert.False(t, ok, "a code at or before the last accepted step must not verify again")
+}
+
+func TestVerifyAfterStillAcceptsLaterStepWithinDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0).UTC()
+	first := Generate(rfc6238Secret, now)
+	_, step := VerifyAfter(rfc6238Secret, first, now, 0)
+
+	next := Generate(rfc6238Secret, now.Add(stepPeriod))
+	ok, _ := VerifyAfter(rfc6238Secret, next, now.Add(stepPeriod), step)
+	assert.True(t, ok)
+}